@@ -0,0 +1,123 @@
+/*
+ * Minio Client (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// CopyObject performs a fully server-side copy of srcBucket/srcObject
+// onto the current bucket/object, never pulling any bytes through the
+// client. Copies above multipartThreshold are parallelized across
+// Config.PartsWorkerPoolSize workers using UploadPartCopy, one per part
+// range, instead of a single CopyObject call.
+func (c *s3Client) CopyObject(srcBucket, srcObject string, srcSize int64) error {
+	bucket, object := c.url2BucketAndObject()
+	if object == "" {
+		return iodine.New(InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+
+	if srcSize <= multipartThreshold {
+		return iodine.New(c.api.CopyObject(bucket, object, srcBucket, srcObject), nil)
+	}
+	return iodine.New(c.copyObjectMultipart(bucket, object, srcBucket, srcObject, srcSize), nil)
+}
+
+// copyObjectMultipart copies srcBucket/srcObject in defaultPartSize byte
+// ranges via UploadPartCopy, using up to partsWorkerPoolSize() workers
+// in flight. It resumes any in-progress multipart upload already found
+// for bucket/object the same way putObjectMultipart does, only
+// re-copying parts that aren't already there.
+func (c *s3Client) copyObjectMultipart(bucket, object, srcBucket, srcObject string, srcSize int64) error {
+	uploadID, uploadedParts, err := c.resumeMultipartUpload(bucket, object, srcSize)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	if uploadID == "" {
+		uploadID, err = c.api.NewMultipartUpload(bucket, object)
+		if err != nil {
+			return iodine.New(err, nil)
+		}
+	}
+
+	// any return past this point leaves an incomplete multipart upload
+	// on the server unless it is explicitly aborted; a failed copy
+	// would otherwise keep being "resumed" against the same doomed
+	// upload ID forever
+	completedOK := false
+	defer func() {
+		if !completedOK {
+			c.api.AbortMultipartUpload(bucket, object, uploadID)
+		}
+	}()
+
+	totalParts := totalPartsFor(srcSize)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, c.partsWorkerPoolSize())
+	errCh := make(chan error, totalParts)
+	completed := make([]completedPart, 0, totalParts)
+	var completedMu sync.Mutex
+
+	for partNumber := 1; partNumber <= totalParts; partNumber++ {
+		if etag, ok := uploadedParts[partNumber]; ok {
+			// already copied in a previous attempt
+			completedMu.Lock()
+			completed = append(completed, completedPart{PartNumber: partNumber, ETag: etag})
+			completedMu.Unlock()
+			continue
+		}
+
+		start := int64(partNumber-1) * defaultPartSize
+		end := start + defaultPartSize - 1
+		if partNumber == totalParts {
+			end = srcSize - 1
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(partNumber int, start, end int64) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			byteRange := fmt.Sprintf("bytes=%d-%d", start, end)
+			etag, err := c.api.UploadPartCopy(bucket, object, uploadID, partNumber, srcBucket, srcObject, byteRange)
+			if err != nil {
+				errCh <- iodine.New(err, nil)
+				return
+			}
+			completedMu.Lock()
+			completed = append(completed, completedPart{PartNumber: partNumber, ETag: etag})
+			completedMu.Unlock()
+		}(partNumber, start, end)
+	}
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		return err
+	}
+
+	sort.Sort(byPartNumber(completed))
+	if err := c.api.CompleteMultipartUpload(bucket, object, uploadID, completed); err != nil {
+		return err
+	}
+	completedOK = true
+	return nil
+}
@@ -0,0 +1,227 @@
+/*
+ * Minio Client (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"io"
+	"io/ioutil"
+	"sort"
+	"sync"
+
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// byPartNumber sorts completedPart entries into the ascending order
+// CompleteMultipartUpload requires.
+type byPartNumber []completedPart
+
+func (b byPartNumber) Len() int           { return len(b) }
+func (b byPartNumber) Less(i, j int) bool { return b[i].PartNumber < b[j].PartNumber }
+func (b byPartNumber) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+
+// multipartThreshold is the object size above which PutObject and
+// CopyObject switch from a single request to a multipart upload.
+const multipartThreshold = 64 * 1024 * 1024 // 64 MiB
+
+// defaultPartSize is the size of each part a multipart upload is split
+// into, save for the last part which carries the remainder.
+const defaultPartSize = 64 * 1024 * 1024 // 64 MiB
+
+// defaultPartsWorkerPoolSize is how many parts are uploaded concurrently
+// when Config.PartsWorkerPoolSize is left unset.
+const defaultPartsWorkerPoolSize = 4
+
+// completedPart is a single entry of the <CompleteMultipartUpload> body,
+// identifying an already-uploaded part by number and ETag.
+type completedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+// partsWorkerPoolSize returns the configured worker pool size, falling
+// back to defaultPartsWorkerPoolSize when unset.
+func (c *s3Client) partsWorkerPoolSize() int {
+	if c.partsWorkers > 0 {
+		return c.partsWorkers
+	}
+	return defaultPartsWorkerPoolSize
+}
+
+// totalPartsFor returns how many defaultPartSize-sized parts size splits
+// into, with the last part carrying the remainder.
+func totalPartsFor(size int64) int {
+	return int((size + defaultPartSize - 1) / defaultPartSize)
+}
+
+// partSizeFor returns the expected size of partNumber out of totalParts
+// parts covering size bytes.
+func partSizeFor(partNumber, totalParts int, size int64) int64 {
+	if partNumber == totalParts {
+		return size - int64(totalParts-1)*defaultPartSize
+	}
+	return defaultPartSize
+}
+
+// resumeMultipartUpload looks for an in-progress multipart upload for
+// bucket/object and, if found, returns its uploadID along with the set
+// of part numbers already uploaded so they are not re-sent. size is the
+// size of the data about to be (re-)uploaded; if the discovered upload's
+// parts don't line up with it byte-for-byte -- the local source changed
+// between attempts, shifting part boundaries -- the stale upload is
+// aborted and a fresh one is started instead of risking a final object
+// assembled from a mix of old and new content.
+func (c *s3Client) resumeMultipartUpload(bucket, object string, size int64) (uploadID string, uploadedParts map[int]string, err error) {
+	foundID, found, err := c.api.ListMultipartUploads(bucket, object)
+	if err != nil {
+		return "", nil, iodine.New(err, nil)
+	}
+	uploadedParts = make(map[int]string)
+	if !found {
+		return "", uploadedParts, nil
+	}
+	parts, err := c.api.ListObjectParts(bucket, object, foundID)
+	if err != nil {
+		return "", nil, iodine.New(err, nil)
+	}
+
+	totalParts := totalPartsFor(size)
+	for _, part := range parts {
+		if part.PartNumber < 1 || part.PartNumber > totalParts || part.Size != partSizeFor(part.PartNumber, totalParts, size) {
+			if err := c.api.AbortMultipartUpload(bucket, object, foundID); err != nil {
+				return "", nil, iodine.New(err, nil)
+			}
+			return "", make(map[int]string), nil
+		}
+	}
+	for _, part := range parts {
+		uploadedParts[part.PartNumber] = part.ETag
+	}
+	return foundID, uploadedParts, nil
+}
+
+// putObjectMultipart splits data into defaultPartSize chunks and uploads
+// them with up to partsWorkerPoolSize() workers in flight, resuming any
+// upload ID already in progress for bucket/object and skipping parts
+// that were uploaded in a previous attempt. progress, if non-nil,
+// receives the bytes of data as they are read off the stream, including
+// the bytes of skipped parts, before each part's upload completes.
+func (c *s3Client) putObjectMultipart(bucket, object string, size int64, data io.Reader, progress io.Writer) error {
+	if progress != nil {
+		data = io.TeeReader(data, progress)
+	}
+	uploadID, uploadedParts, err := c.resumeMultipartUpload(bucket, object, size)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	if uploadID == "" {
+		uploadID, err = c.api.NewMultipartUpload(bucket, object)
+		if err != nil {
+			return iodine.New(err, nil)
+		}
+	}
+
+	// any return past this point leaves an incomplete multipart upload
+	// on the server unless it is explicitly aborted; a failed upload
+	// would otherwise keep being "resumed" against the same doomed
+	// upload ID forever
+	completedOK := false
+	defer func() {
+		if !completedOK {
+			c.api.AbortMultipartUpload(bucket, object, uploadID)
+		}
+	}()
+
+	totalParts := totalPartsFor(size)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, c.partsWorkerPoolSize())
+	errCh := make(chan error, totalParts)
+	completed := make([]completedPart, 0, totalParts)
+	var completedMu sync.Mutex
+
+	for partNumber := 1; partNumber <= totalParts; partNumber++ {
+		partSize := partSizeFor(partNumber, totalParts, size)
+
+		if etag, ok := uploadedParts[partNumber]; ok {
+			// already uploaded in a previous attempt, just skip past its
+			// bytes in the stream and record it as complete
+			if _, err := io.CopyN(ioutil.Discard, data, partSize); err != nil {
+				return iodine.New(err, nil)
+			}
+			completedMu.Lock()
+			completed = append(completed, completedPart{PartNumber: partNumber, ETag: etag})
+			completedMu.Unlock()
+			continue
+		}
+
+		// parts must be read off data in order since it may not be
+		// seekable, but the upload of each buffered part can proceed
+		// concurrently with reading/uploading the next one
+		buf := make([]byte, partSize)
+		if _, err := io.ReadFull(data, buf); err != nil {
+			return iodine.New(err, nil)
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(partNumber int, buf []byte) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			etag, err := c.api.PutObjectPart(bucket, object, uploadID, partNumber, int64(len(buf)), newByteReader(buf))
+			if err != nil {
+				errCh <- iodine.New(err, nil)
+				return
+			}
+			completedMu.Lock()
+			completed = append(completed, completedPart{PartNumber: partNumber, ETag: etag})
+			completedMu.Unlock()
+		}(partNumber, buf)
+	}
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		return err
+	}
+
+	sort.Sort(byPartNumber(completed))
+	if err := c.api.CompleteMultipartUpload(bucket, object, uploadID, completed); err != nil {
+		return iodine.New(err, nil)
+	}
+	completedOK = true
+	return nil
+}
+
+// byteReader is a minimal io.Reader over an in-memory buffer, used so
+// each part's upload goroutine reads from its own buffer independent of
+// the shared input stream.
+type byteReader struct {
+	buf []byte
+	off int
+}
+
+func newByteReader(buf []byte) *byteReader {
+	return &byteReader{buf: buf}
+}
+
+func (r *byteReader) Read(p []byte) (int, error) {
+	if r.off >= len(r.buf) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.buf[r.off:])
+	r.off += n
+	return n, nil
+}
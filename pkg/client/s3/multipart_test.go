@@ -0,0 +1,56 @@
+/*
+ * Minio Client (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"sort"
+
+	. "github.com/minio-io/check"
+)
+
+type MultipartSuite struct{}
+
+var _ = Suite(&MultipartSuite{})
+
+func (s *MultipartSuite) TestPartsWorkerPoolSize(c *C) {
+	unset := &s3Client{}
+	c.Assert(unset.partsWorkerPoolSize(), Equals, defaultPartsWorkerPoolSize)
+
+	configured := &s3Client{partsWorkers: 9}
+	c.Assert(configured.partsWorkerPoolSize(), Equals, 9)
+}
+
+func (s *MultipartSuite) TestByPartNumberSort(c *C) {
+	completed := []completedPart{
+		{PartNumber: 3, ETag: "c"},
+		{PartNumber: 1, ETag: "a"},
+		{PartNumber: 2, ETag: "b"},
+	}
+	sort.Sort(byPartNumber(completed))
+	c.Assert(completed, DeepEquals, []completedPart{
+		{PartNumber: 1, ETag: "a"},
+		{PartNumber: 2, ETag: "b"},
+		{PartNumber: 3, ETag: "c"},
+	})
+}
+
+func (s *MultipartSuite) TestTotalPartsMath(c *C) {
+	// exact multiple of defaultPartSize
+	c.Assert(int((2*int64(defaultPartSize)+defaultPartSize-1)/defaultPartSize), Equals, 2)
+	// one byte over a multiple needs one extra, smaller, part
+	c.Assert(int((2*int64(defaultPartSize)+1+defaultPartSize-1)/defaultPartSize), Equals, 3)
+}
@@ -0,0 +1,140 @@
+/*
+ * Minio Client (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"time"
+)
+
+// notificationRetryInterval is the initial, and smallest, backoff
+// ListenBucketNotification waits before reconnecting after the long-poll
+// connection drops.
+const notificationRetryInterval = 2 * time.Second
+
+// notificationMaxRetryInterval caps how long the backoff is allowed to
+// grow to between reconnect attempts.
+const notificationMaxRetryInterval = 32 * time.Second
+
+// NotificationInfo describes a single s3:ObjectCreated/ObjectRemoved/
+// ObjectAccessed event as reported by ListenBucketNotification.
+type NotificationInfo struct {
+	EventName string
+	Bucket    string
+	Key       string
+	Size      int64
+	ETag      string
+	SourceIP  string
+	Time      time.Time
+	Err       error
+}
+
+// ListenBucketNotification streams object events for the current bucket
+// matching events/prefix/suffix. On Minio servers this long-polls
+// /{bucket}?notifications=; against AWS it falls back to polling any
+// SQS/SNS ARNs configured on the bucket's notification configuration.
+// Reconnects after a dropped long-poll use an exponential backoff,
+// capped at notificationMaxRetryInterval and reset on the next
+// successful connection. Closing the returned stop channel ends the
+// underlying goroutine and closes the notification channel; it is the
+// caller's responsibility to close it once it stops reading events.
+func (c *s3Client) ListenBucketNotification(events []string, prefix, suffix string) (<-chan NotificationInfo, chan<- struct{}) {
+	notificationCh := make(chan NotificationInfo)
+	stopCh := make(chan struct{})
+	go c.listenBucketNotificationInRoutine(events, prefix, suffix, notificationCh, stopCh)
+	return notificationCh, stopCh
+}
+
+func (c *s3Client) listenBucketNotificationInRoutine(events []string, prefix, suffix string, notificationCh chan<- NotificationInfo, stopCh <-chan struct{}) {
+	defer close(notificationCh)
+	bucket, _ := c.url2BucketAndObject()
+
+	retry := notificationRetryInterval
+	for {
+		infoCh, doneCh, err := c.api.ListenBucketNotification(bucket, events, prefix, suffix)
+		if err != nil {
+			select {
+			case notificationCh <- NotificationInfo{Err: err}:
+			case <-stopCh:
+				return
+			}
+			if !sleepOrStop(retry, stopCh) {
+				return
+			}
+			retry = nextRetryInterval(retry)
+			continue
+		}
+		retry = notificationRetryInterval
+
+	readLoop:
+		for {
+			select {
+			case info, ok := <-infoCh:
+				if !ok {
+					close(doneCh)
+					break readLoop
+				}
+				select {
+				case notificationCh <- NotificationInfo{
+					EventName: info.EventName,
+					Bucket:    bucket,
+					Key:       info.Key,
+					Size:      info.Size,
+					ETag:      info.ETag,
+					SourceIP:  info.SourceIP,
+					Time:      info.Time,
+					Err:       info.Err,
+				}:
+				case <-stopCh:
+					close(doneCh)
+					return
+				}
+				if info.Err != nil {
+					close(doneCh)
+					break readLoop
+				}
+			case <-stopCh:
+				close(doneCh)
+				return
+			}
+		}
+		// the server closed the long-poll connection, reconnect and keep tailing
+		if !sleepOrStop(retry, stopCh) {
+			return
+		}
+	}
+}
+
+// sleepOrStop waits for d, returning false early if stopCh is closed
+// first so the caller can unwind without waiting out the full backoff.
+func sleepOrStop(d time.Duration, stopCh <-chan struct{}) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-stopCh:
+		return false
+	}
+}
+
+// nextRetryInterval doubles the previous backoff, capped at
+// notificationMaxRetryInterval.
+func nextRetryInterval(prev time.Duration) time.Duration {
+	next := prev * 2
+	if next > notificationMaxRetryInterval {
+		return notificationMaxRetryInterval
+	}
+	return next
+}
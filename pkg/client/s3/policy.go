@@ -0,0 +1,216 @@
+/*
+ * Minio Client (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"encoding/json"
+	"strings"
+
+	s3 "github.com/minio/minio-go"
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// BucketPolicy is the canned, human-friendly policy level mc exposes.
+// It collapses the handful of statements a full IAM policy document
+// can express for anonymous access down to four levels.
+type BucketPolicy string
+
+// Canned policy levels accepted by GetBucketPolicy/SetBucketPolicy.
+const (
+	PolicyNone     BucketPolicy = "none"
+	PolicyDownload BucketPolicy = "download"
+	PolicyUpload   BucketPolicy = "upload"
+	PolicyPublic   BucketPolicy = "public"
+)
+
+// policyStatement mirrors a single statement in a bucket policy's JSON
+// document, trimmed to the fields the canned policy translator needs.
+type policyStatement struct {
+	Sid       string      `json:"Sid,omitempty"`
+	Effect    string      `json:"Effect"`
+	Principal interface{} `json:"Principal"`
+	Action    interface{} `json:"Action"`
+	Resource  interface{} `json:"Resource"`
+}
+
+// policyDocument is the full JSON IAM policy document S3 stores and
+// returns for GET/PUT /{bucket}?policy.
+type policyDocument struct {
+	Version    string            `json:"Version"`
+	Statements []policyStatement `json:"Statement"`
+}
+
+const policyDocVersion = "2012-10-17"
+
+var downloadActions = []string{"s3:GetObject"}
+var uploadActions = []string{"s3:PutObject", "s3:ListMultipartUploadParts", "s3:AbortMultipartUpload"}
+
+// policyResource builds the ARN resource a canned policy statement grants
+// access to. The "/" always separates bucket from prefix so a bare
+// bucket (prefix == "") resolves to "bucket/*" rather than "bucket*",
+// which would also match any other bucket sharing that name prefix.
+func policyResource(bucket, prefix string) string {
+	return "arn:aws:s3:::" + bucket + "/" + prefix + "*"
+}
+
+// mcStatementID marks the statements this translator owns so repeated
+// SetBucketPolicy calls only ever touch their own statements, leaving
+// any hand-authored ones alone.
+func mcStatementID(bucket, prefix, action string) string {
+	return "mc-" + action + "-" + bucket + "-" + prefix
+}
+
+// GetBucketPolicy fetches the policy document for bucket/prefix and
+// translates it into the canned level that best matches its statements.
+func (c *s3Client) GetBucketPolicy(prefix string) (BucketPolicy, error) {
+	bucket, _ := c.url2BucketAndObject()
+	doc, err := c.getPolicyDocument(bucket)
+	if err != nil {
+		return PolicyNone, iodine.New(err, nil)
+	}
+	download := doc.hasStatement(mcStatementID(bucket, prefix, "download"))
+	upload := doc.hasStatement(mcStatementID(bucket, prefix, "upload"))
+	switch {
+	case download && upload:
+		return PolicyPublic, nil
+	case download:
+		return PolicyDownload, nil
+	case upload:
+		return PolicyUpload, nil
+	default:
+		return PolicyNone, nil
+	}
+}
+
+// SetBucketPolicy merges the statements implied by policy into any
+// existing policy document for bucket/prefix, only ever adding or
+// removing the statements it recognizes as its own.
+func (c *s3Client) SetBucketPolicy(prefix string, policy BucketPolicy) error {
+	bucket, _ := c.url2BucketAndObject()
+	doc, err := c.getPolicyDocument(bucket)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	resource := policyResource(bucket, prefix)
+
+	doc.removeStatement(mcStatementID(bucket, prefix, "download"))
+	doc.removeStatement(mcStatementID(bucket, prefix, "upload"))
+
+	switch policy {
+	case PolicyDownload, PolicyPublic:
+		doc.addStatement(mcStatementID(bucket, prefix, "download"), resource, downloadActions)
+	}
+	switch policy {
+	case PolicyUpload, PolicyPublic:
+		doc.addStatement(mcStatementID(bucket, prefix, "upload"), resource, uploadActions)
+	}
+
+	if len(doc.Statements) == 0 {
+		return iodine.New(c.api.RemoveBucketPolicy(bucket), nil)
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return iodine.New(err, nil)
+	}
+	return iodine.New(c.api.PutBucketPolicy(bucket, data), nil)
+}
+
+// ListBucketPolicies returns every prefix starting with prefix that this
+// translator recognizes a canned policy for, keyed by prefix.
+func (c *s3Client) ListBucketPolicies(prefix string) (map[string]BucketPolicy, error) {
+	bucket, _ := c.url2BucketAndObject()
+	doc, err := c.getPolicyDocument(bucket)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	policies := make(map[string]BucketPolicy)
+	downloadPrefix := "mc-download-" + bucket + "-"
+	uploadPrefix := "mc-upload-" + bucket + "-"
+	for _, stmt := range doc.Statements {
+		if strings.HasPrefix(stmt.Sid, downloadPrefix) {
+			p := strings.TrimPrefix(stmt.Sid, downloadPrefix)
+			if strings.HasPrefix(p, prefix) {
+				policies[p] = mergePolicy(policies[p], PolicyDownload)
+			}
+		}
+		if strings.HasPrefix(stmt.Sid, uploadPrefix) {
+			p := strings.TrimPrefix(stmt.Sid, uploadPrefix)
+			if strings.HasPrefix(p, prefix) {
+				policies[p] = mergePolicy(policies[p], PolicyUpload)
+			}
+		}
+	}
+	return policies, nil
+}
+
+func mergePolicy(existing, add BucketPolicy) BucketPolicy {
+	switch {
+	case existing == "":
+		return add
+	case existing != add:
+		return PolicyPublic
+	default:
+		return existing
+	}
+}
+
+// getPolicyDocument fetches and parses the current policy document for
+// bucket, returning an empty document if none has been set yet.
+func (c *s3Client) getPolicyDocument(bucket string) (*policyDocument, error) {
+	data, err := c.api.GetBucketPolicy(bucket)
+	if err != nil {
+		if s3.ToErrorResponse(err).Code == "NoSuchBucketPolicy" {
+			return &policyDocument{Version: policyDocVersion}, nil
+		}
+		return nil, iodine.New(err, nil)
+	}
+	doc := new(policyDocument)
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, iodine.New(err, nil)
+	}
+	return doc, nil
+}
+
+func (doc *policyDocument) hasStatement(sid string) bool {
+	for _, stmt := range doc.Statements {
+		if stmt.Sid == sid {
+			return true
+		}
+	}
+	return false
+}
+
+func (doc *policyDocument) removeStatement(sid string) {
+	var kept []policyStatement
+	for _, stmt := range doc.Statements {
+		if stmt.Sid != sid {
+			kept = append(kept, stmt)
+		}
+	}
+	doc.Statements = kept
+}
+
+func (doc *policyDocument) addStatement(sid, resource string, actions []string) {
+	doc.Version = policyDocVersion
+	doc.Statements = append(doc.Statements, policyStatement{
+		Sid:       sid,
+		Effect:    "Allow",
+		Principal: map[string]string{"AWS": "*"},
+		Action:    actions,
+		Resource:  []string{resource},
+	})
+}
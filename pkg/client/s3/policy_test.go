@@ -0,0 +1,55 @@
+/*
+ * Minio Client (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"testing"
+
+	. "github.com/minio-io/check"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type PolicySuite struct{}
+
+var _ = Suite(&PolicySuite{})
+
+func (s *PolicySuite) TestPolicyResource(c *C) {
+	// a bare bucket must get an explicit "/" before the wildcard, else
+	// "mybucket*" as an IAM resource also matches "mybucket-backups"
+	c.Assert(policyResource("mybucket", ""), Equals, "arn:aws:s3:::mybucket/*")
+	c.Assert(policyResource("mybucket", "photos"), Equals, "arn:aws:s3:::mybucket/photos*")
+	c.Assert(policyResource("mybucket", "photos/"), Equals, "arn:aws:s3:::mybucket/photos/*")
+}
+
+func (s *PolicySuite) TestMergePolicy(c *C) {
+	c.Assert(mergePolicy("", PolicyDownload), Equals, PolicyDownload)
+	c.Assert(mergePolicy(PolicyDownload, PolicyDownload), Equals, PolicyDownload)
+	c.Assert(mergePolicy(PolicyDownload, PolicyUpload), Equals, PolicyPublic)
+	c.Assert(mergePolicy(PolicyUpload, PolicyDownload), Equals, PolicyPublic)
+}
+
+func (s *PolicySuite) TestStatementRoundTrip(c *C) {
+	doc := &policyDocument{Version: policyDocVersion}
+	doc.addStatement("mc-download-mybucket-", policyResource("mybucket", ""), downloadActions)
+	c.Assert(doc.hasStatement("mc-download-mybucket-"), Equals, true)
+	c.Assert(doc.hasStatement("mc-upload-mybucket-"), Equals, false)
+
+	doc.removeStatement("mc-download-mybucket-")
+	c.Assert(doc.hasStatement("mc-download-mybucket-"), Equals, false)
+	c.Assert(doc.Statements, HasLen, 0)
+}
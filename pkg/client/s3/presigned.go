@@ -0,0 +1,75 @@
+/*
+ * Minio Client (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// maxExpiry is the longest expiry S3 sigv4 presigned URLs allow.
+const maxExpiry = 7 * 24 * time.Hour
+
+// PresignedGetObject returns a time-limited URL that performs a GET on
+// the current bucket/object without requiring the caller to hold
+// credentials. reqParams are added as signed query parameters, useful
+// for overriding response headers such as response-content-disposition.
+func (c *s3Client) PresignedGetObject(expires time.Duration, reqParams url.Values) (string, error) {
+	if expires > maxExpiry {
+		return "", iodine.New(InvalidExpiryError{Expires: expires, Max: maxExpiry}, nil)
+	}
+	bucket, object := c.url2BucketAndObject()
+	if object == "" {
+		return "", iodine.New(InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	u, err := c.api.PresignedGetObject(bucket, object, expires, reqParams)
+	if err != nil {
+		return "", iodine.New(err, nil)
+	}
+	return u, nil
+}
+
+// PresignedPutObject returns a time-limited URL that performs a PUT on
+// the current bucket/object without requiring the caller to hold
+// credentials.
+func (c *s3Client) PresignedPutObject(expires time.Duration) (string, error) {
+	if expires > maxExpiry {
+		return "", iodine.New(InvalidExpiryError{Expires: expires, Max: maxExpiry}, nil)
+	}
+	bucket, object := c.url2BucketAndObject()
+	if object == "" {
+		return "", iodine.New(InvalidQueryURL{URL: c.hostURL.String()}, nil)
+	}
+	u, err := c.api.PresignedPutObject(bucket, object, expires)
+	if err != nil {
+		return "", iodine.New(err, nil)
+	}
+	return u, nil
+}
+
+// InvalidExpiryError is returned when a requested presigned URL expiry
+// exceeds what S3 sigv4 signatures allow.
+type InvalidExpiryError struct {
+	Expires time.Duration
+	Max     time.Duration
+}
+
+func (e InvalidExpiryError) Error() string {
+	return "Invalid expiry " + e.Expires.String() + ", expiry cannot exceed " + e.Max.String()
+}
@@ -0,0 +1,98 @@
+/*
+ * Minio Client (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// maxDeleteObjects is the largest number of keys S3's multi-object
+// delete accepts in a single POST /{bucket}?delete request.
+const maxDeleteObjects = 1000
+
+// RemoveError pairs a failed key with the error S3 returned for it.
+type RemoveError struct {
+	Key string
+	Err error
+}
+
+// RemoveObjects batches the keys read off objectCh into groups of up to
+// maxDeleteObjects and issues a multi-object delete for each batch,
+// forwarding any per-key errors on the returned channel. The channel is
+// closed once objectCh is drained and every batch has been sent.
+func (c *s3Client) RemoveObjects(objectCh <-chan string) <-chan RemoveError {
+	errorCh := make(chan RemoveError)
+	go c.removeObjectsInRoutine(objectCh, errorCh)
+	return errorCh
+}
+
+func (c *s3Client) removeObjectsInRoutine(objectCh <-chan string, errorCh chan<- RemoveError) {
+	defer close(errorCh)
+	bucket, _ := c.url2BucketAndObject()
+
+	batch := make([]string, 0, maxDeleteObjects)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		for _, removeErr := range c.deleteObjectsBatch(bucket, batch) {
+			errorCh <- removeErr
+		}
+		batch = batch[:0]
+	}
+
+	for object := range objectCh {
+		batch = append(batch, object)
+		if len(batch) == maxDeleteObjects {
+			flush()
+		}
+	}
+	flush()
+}
+
+// deleteObjectsBatch issues a single multi-object delete for up to
+// maxDeleteObjects keys and returns the per-key errors S3 reported.
+func (c *s3Client) deleteObjectsBatch(bucket string, keys []string) []RemoveError {
+	result, err := c.api.DeleteObjects(bucket, keys)
+	if err != nil {
+		// the whole batch failed, attribute the error to every key in it
+		removeErrs := make([]RemoveError, len(keys))
+		for i, key := range keys {
+			removeErrs[i] = RemoveError{Key: key, Err: iodine.New(err, nil)}
+		}
+		return removeErrs
+	}
+	var removeErrs []RemoveError
+	for _, failed := range result.Errors {
+		removeErrs = append(removeErrs, RemoveError{
+			Key: failed.Key,
+			Err: iodine.New(ObjectDeleteError{Key: failed.Key, Code: failed.Code, Message: failed.Message}, nil),
+		})
+	}
+	return removeErrs
+}
+
+// ObjectDeleteError wraps a single <Error> entry out of a DeleteResult.
+type ObjectDeleteError struct {
+	Key     string
+	Code    string
+	Message string
+}
+
+func (e ObjectDeleteError) Error() string {
+	return "Failed to remove `" + e.Key + "`: " + e.Code + ": " + e.Message
+}
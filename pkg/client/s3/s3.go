@@ -44,6 +44,10 @@ type Config struct {
 	// Used for SSL transport layer
 	CertPEM string
 	KeyPEM  string
+
+	// Number of parts uploaded or copied concurrently during a
+	// multipart PutObject/CopyObject, defaults to defaultPartsWorkerPoolSize
+	PartsWorkerPoolSize int
 }
 
 // TLSConfig - TLS cert and key configuration
@@ -53,8 +57,9 @@ type TLSConfig struct {
 }
 
 type s3Client struct {
-	api     s3.API
-	hostURL *client.URL
+	api          s3.API
+	hostURL      *client.URL
+	partsWorkers int
 }
 
 // url2Regions s3 region map used by bucket location constraint
@@ -106,7 +111,7 @@ func New(config *Config) (client.Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &s3Client{api: api, hostURL: u}, nil
+	return &s3Client{api: api, hostURL: u, partsWorkers: config.PartsWorkerPoolSize}, nil
 }
 
 // URL get url
@@ -124,14 +129,20 @@ func (c *s3Client) GetObject(offset, length int64) (io.ReadCloser, int64, error)
 	return reader, metadata.Size, nil
 }
 
-// PutObject - put object
-func (c *s3Client) PutObject(size int64, data io.Reader) error {
+// PutObject - put object. progress, if non-nil, is written to as bytes
+// are read off data so callers can render a live progress bar, both for
+// uploads that fit in a single PUT and for multipart uploads.
+func (c *s3Client) PutObject(size int64, data io.Reader, progress io.Writer) error {
 	// md5 is purposefully ignored since AmazonS3 does not return proper md5sum
 	// for a multipart upload and there is no need to cross verify,
 	// invidual parts are properly verified
 	bucket, object := c.url2BucketAndObject()
-	// TODO - bump individual part size from default, if needed
-	// s3.DefaultPartSize = 1024 * 1024 * 100
+	if size > multipartThreshold {
+		return iodine.New(c.putObjectMultipart(bucket, object, size, data, progress), nil)
+	}
+	if progress != nil {
+		data = io.TeeReader(data, progress)
+	}
 	err := c.api.PutObject(bucket, object, size, data)
 	if err != nil {
 		return iodine.New(err, nil)
@@ -0,0 +1,315 @@
+/*
+ * Minio Client (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/minio/minio/pkg/iodine"
+)
+
+// CSVInputOptions describes how an input CSV object is framed.
+type CSVInputOptions struct {
+	FileHeaderInfo  string
+	RecordDelimiter string
+	FieldDelimiter  string
+	QuoteCharacter  string
+	QuoteEscapeChar string
+	Comments        string
+}
+
+// CSVOutputOptions describes how matching records are framed on the way out.
+type CSVOutputOptions struct {
+	RecordDelimiter string
+	FieldDelimiter  string
+	QuoteCharacter  string
+	QuoteEscapeChar string
+	QuoteFields     string
+}
+
+// JSONInputOptions describes how an input JSON object is framed.
+// Type is either "DOCUMENT" or "LINES".
+type JSONInputOptions struct {
+	Type string
+}
+
+// JSONOutputOptions describes how matching records are framed on the way out.
+type JSONOutputOptions struct {
+	RecordDelimiter string
+}
+
+// ParquetInputOptions is a placeholder, Parquet objects carry their own
+// schema and require no additional framing information.
+type ParquetInputOptions struct{}
+
+// InputSerialization describes the format of the object being queried.
+// Only one of CSV, JSON or Parquet should be set. CompressionType is one
+// of "NONE", "GZIP" or "BZIP2" and applies to any of the three formats.
+type InputSerialization struct {
+	CompressionType string
+	CSV             *CSVInputOptions
+	JSON            *JSONInputOptions
+	Parquet         *ParquetInputOptions
+}
+
+// OutputSerialization describes the format records are streamed back in.
+// Only one of CSV or JSON should be set.
+type OutputSerialization struct {
+	CSV  *CSVOutputOptions
+	JSON *JSONOutputOptions
+}
+
+// SelectRequest carries everything needed to run a SQL expression against
+// an object via S3 Select.
+type SelectRequest struct {
+	Expression     string
+	ExpressionType string // only "SQL" is currently supported
+	Input          InputSerialization
+	Output         OutputSerialization
+}
+
+// Stats are the byte counters S3 Select reports once a query completes,
+// delivered on SelectObjectReader's Stats channel.
+type Stats struct {
+	BytesScanned   int64 `xml:"BytesScanned"`
+	BytesProcessed int64 `xml:"BytesProcessed"`
+	BytesReturned  int64 `xml:"BytesReturned"`
+}
+
+// SelectObjectReader is the io.ReadCloser returned by SelectObjectContent.
+// Reading from it yields the concatenated payloads of every `Records`
+// event; Stats receives the final `Stats` event, if the server sent one,
+// just before the stream closes.
+type SelectObjectReader struct {
+	Stats <-chan Stats
+
+	pr *io.PipeReader
+	pw *io.PipeWriter
+	rc io.ReadCloser
+}
+
+func (s *SelectObjectReader) Read(p []byte) (int, error) {
+	return s.pr.Read(p)
+}
+
+// Close aborts the underlying HTTP body if the caller stops reading
+// before the event stream has been fully drained.
+func (s *SelectObjectReader) Close() error {
+	s.pr.Close()
+	return s.rc.Close()
+}
+
+// selectRequestXML is the XML wire format POSTed to ?select&select-type=2.
+type selectRequestXML struct {
+	XMLName            xml.Name `xml:"SelectObjectContentRequest"`
+	Expression         string   `xml:"Expression"`
+	ExpressionType     string   `xml:"ExpressionType"`
+	InputSerialization struct {
+		CompressionType string            `xml:"CompressionType,omitempty"`
+		CSV             *CSVInputOptions  `xml:"CSV,omitempty"`
+		JSON            *JSONInputOptions `xml:"JSON,omitempty"`
+		Parquet         *struct{}         `xml:"Parquet,omitempty"`
+	} `xml:"InputSerialization"`
+	OutputSerialization struct {
+		CSV  *CSVOutputOptions  `xml:"CSV,omitempty"`
+		JSON *JSONOutputOptions `xml:"JSON,omitempty"`
+	} `xml:"OutputSerialization"`
+}
+
+// eventStreamMessageError surfaces a `:error-code`/`:error-message` pair
+// carried as an event stream header rather than an HTTP status code.
+type eventStreamMessageError struct {
+	Code    string
+	Message string
+}
+
+func (e eventStreamMessageError) Error() string {
+	return "s3 select: " + e.Code + ": " + e.Message
+}
+
+// SelectObjectContent runs req.Expression against bucket/object and
+// streams the matching records back. Progress and Stats events are not
+// forwarded on the reader itself, only the final Stats summary is,
+// via SelectObjectReader.Stats.
+func (c *s3Client) SelectObjectContent(bucket, object string, req SelectRequest) (io.ReadCloser, error) {
+	var body selectRequestXML
+	body.Expression = req.Expression
+	body.ExpressionType = req.ExpressionType
+	body.InputSerialization.CompressionType = req.Input.CompressionType
+	body.InputSerialization.CSV = req.Input.CSV
+	body.InputSerialization.JSON = req.Input.JSON
+	if req.Input.Parquet != nil {
+		body.InputSerialization.Parquet = &struct{}{}
+	}
+	body.OutputSerialization.CSV = req.Output.CSV
+	body.OutputSerialization.JSON = req.Output.JSON
+
+	payload, err := xml.Marshal(body)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+
+	rc, err := c.api.SelectObjectContent(bucket, object, payload)
+	if err != nil {
+		return nil, iodine.New(err, nil)
+	}
+
+	statsCh := make(chan Stats, 1)
+	pr, pw := io.Pipe()
+	reader := &SelectObjectReader{Stats: statsCh, pr: pr, pw: pw, rc: rc}
+	go decodeEventStream(rc, pw, statsCh)
+	return reader, nil
+}
+
+// eventStreamHeader is a single name/type/value triple from the headers
+// portion of an event stream message.
+type eventStreamHeader struct {
+	name  string
+	value string
+}
+
+// decodeEventStream parses the AWS event stream framing documented at
+// http://docs.aws.amazon.com/AmazonS3/latest/API/RESTSelectObjectAppendix.html
+// and forwards `Records` payloads to pw, closing pw once an `End` event
+// or an error is seen.
+func decodeEventStream(rc io.ReadCloser, pw *io.PipeWriter, statsCh chan<- Stats) {
+	defer close(statsCh)
+	defer rc.Close()
+
+	var prelude [12]byte
+	for {
+		if _, err := io.ReadFull(rc, prelude[:]); err != nil {
+			if err == io.EOF {
+				pw.Close()
+				return
+			}
+			pw.CloseWithError(iodine.New(err, nil))
+			return
+		}
+		totalLen := binary.BigEndian.Uint32(prelude[0:4])
+		headersLen := binary.BigEndian.Uint32(prelude[4:8])
+		preludeCRC := binary.BigEndian.Uint32(prelude[8:12])
+		if crc32.ChecksumIEEE(prelude[0:8]) != preludeCRC {
+			pw.CloseWithError(iodine.New(ErrInvalidEventStreamCRC{}, nil))
+			return
+		}
+
+		// totalLen covers the 12-byte prelude, the headers, the
+		// payload and a trailing 4-byte message CRC, so anything
+		// claiming less than 16 bytes or headers longer than the
+		// message itself is corrupt or truncated framing.
+		if totalLen < 16 || headersLen > totalLen-16 {
+			pw.CloseWithError(iodine.New(ErrInvalidEventStreamFraming{TotalLength: totalLen, HeadersLength: headersLen}, nil))
+			return
+		}
+
+		remaining := make([]byte, totalLen-12)
+		if _, err := io.ReadFull(rc, remaining); err != nil {
+			pw.CloseWithError(iodine.New(err, nil))
+			return
+		}
+		headersBuf := remaining[:headersLen]
+		payload := remaining[headersLen : len(remaining)-4]
+		messageCRC := binary.BigEndian.Uint32(remaining[len(remaining)-4:])
+
+		full := make([]byte, 0, len(prelude)+len(remaining)-4)
+		full = append(full, prelude[:]...)
+		full = append(full, remaining[:len(remaining)-4]...)
+		if crc32.ChecksumIEEE(full) != messageCRC {
+			pw.CloseWithError(iodine.New(ErrInvalidEventStreamCRC{}, nil))
+			return
+		}
+
+		headers := parseEventStreamHeaders(headersBuf)
+		messageType := headers[":message-type"]
+		eventType := headers[":event-type"]
+
+		switch messageType {
+		case "error":
+			pw.CloseWithError(iodine.New(eventStreamMessageError{
+				Code:    headers[":error-code"],
+				Message: headers[":error-message"],
+			}, nil))
+			return
+		}
+
+		switch eventType {
+		case "Records":
+			if _, err := pw.Write(payload); err != nil {
+				return
+			}
+		case "Progress", "Stats":
+			var stats struct {
+				Details Stats `xml:"Details"`
+			}
+			if xml.Unmarshal(payload, &stats) == nil && eventType == "Stats" {
+				statsCh <- stats.Details
+			}
+		case "Cont":
+			// keepalive, nothing to do
+		case "End":
+			pw.Close()
+			return
+		}
+	}
+}
+
+func parseEventStreamHeaders(buf []byte) map[string]string {
+	headers := make(map[string]string)
+	r := bytes.NewReader(buf)
+	for r.Len() > 0 {
+		nameLen, err := r.ReadByte()
+		if err != nil {
+			break
+		}
+		name := make([]byte, nameLen)
+		io.ReadFull(r, name)
+		r.ReadByte() // value type, always 7 (string) for the headers we care about
+		var valueLen uint16
+		binary.Read(r, binary.BigEndian, &valueLen)
+		value := make([]byte, valueLen)
+		io.ReadFull(r, value)
+		headers[string(name)] = string(value)
+	}
+	return headers
+}
+
+// ErrInvalidEventStreamFraming is returned when a message's total or
+// headers length is inconsistent with the event stream framing, which
+// would otherwise underflow or panic while slicing the message body.
+type ErrInvalidEventStreamFraming struct {
+	TotalLength   uint32
+	HeadersLength uint32
+}
+
+func (e ErrInvalidEventStreamFraming) Error() string {
+	return fmt.Sprintf("s3 select: invalid event stream message framing (total length %d, headers length %d)", e.TotalLength, e.HeadersLength)
+}
+
+// ErrInvalidEventStreamCRC is returned when a message's prelude CRC32 or
+// trailing message CRC32 does not match the bytes it covers, indicating a
+// corrupted or truncated stream.
+type ErrInvalidEventStreamCRC struct{}
+
+func (e ErrInvalidEventStreamCRC) Error() string {
+	return "s3 select: invalid event stream crc"
+}